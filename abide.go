@@ -2,19 +2,22 @@ package abide
 
 import (
 	"bytes"
+	"context"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
+	"testing"
 )
 
 var (
 	args         *arguments
 	allSnapshots snapshots
-	allSnapMutex sync.Mutex
+	// allSnapMutex guards allSnapshots and idOwners, the two pieces of
+	// state concurrent t.Parallel() subtests actually contend on.
+	allSnapMutex sync.RWMutex
 )
 
 var (
@@ -40,6 +43,9 @@ const (
 	SnapshotGeneric SnapshotType = ""
 	// SnapshotHTTPRespJSON represents a snapshot whose contents are an HTTP response with content type JSON.
 	SnapshotHTTPRespJSON SnapshotType = "HTTPContentTypeJSON"
+	// SnapshotJSONAs represents a snapshot produced by AssertJSONAs/AssertHTTPResponseAs,
+	// containing both the raw JSON body and a Go-syntax dump of the decoded struct.
+	SnapshotJSONAs SnapshotType = "JSONAs"
 )
 
 func init() {
@@ -50,14 +56,52 @@ func init() {
 // Cleanup is an optional method which will execute cleanup operations
 // affiliated with abide testing, such as pruning snapshots.
 func Cleanup() error {
+	allSnapMutex.Lock()
 	for _, s := range allSnapshots {
 		if !s.evaluated && args.shouldUpdate && !args.singleRun {
 			s.shouldRemove = true
+			getActiveReporter().SnapshotUnused(string(s.id))
 			fmt.Printf("Removing unused snapshot `%s`\n", s.id)
 		}
 	}
+	err := allSnapshots.save()
+	allSnapMutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := pruneRetainedRevisions(); err != nil {
+		return err
+	}
 
-	return allSnapshots.save()
+	return flushActiveReporter()
+}
+
+// pruneRetainedRevisions evaluates activeRetentionPolicy against
+// activeStore, if it's a RetainingStore. Stores that don't keep historical
+// revisions (FSStore, S3Store) have nothing to prune.
+func pruneRetainedRevisions() error {
+	store, ok := activeStore.(RetainingStore)
+	if !ok || activeRetentionPolicy == (RetentionPolicy{}) {
+		return nil
+	}
+
+	files, err := store.List(context.Background())
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		removed, err := store.Prune(context.Background(), f.Path, activeRetentionPolicy)
+		if err != nil {
+			return err
+		}
+		if removed > 0 {
+			fmt.Printf("Pruned %d retained revision(s) of `%s`\n", removed, f.Path)
+		}
+	}
+
+	return nil
 }
 
 // CleanupOrFail is an optional method which will behave like
@@ -71,13 +115,20 @@ func CleanupOrFail() error {
 		return Cleanup()
 	}
 
+	allSnapMutex.RLock()
 	failed := 0
 	for _, s := range allSnapshots {
 		if !s.evaluated {
 			failed++
+			getActiveReporter().SnapshotUnused(string(s.id))
 			fmt.Fprintf(os.Stderr, "Unused snapshot `%s`\n", s.id)
 		}
 	}
+	allSnapMutex.RUnlock()
+
+	if err := flushActiveReporter(); err != nil {
+		return err
+	}
 
 	if failed > 0 {
 		return fmt.Errorf("%d unused snapshots", failed)
@@ -89,13 +140,54 @@ func CleanupOrFail() error {
 // snapshotID represents the unique identifier for a snapshot.
 type snapshotID string
 
-// isValid verifies whether the snapshotID is valid. An
-// identifier is considered invalid if it is already in use
-// or it is malformed.
+// isValid verifies whether the snapshotID is well-formed: non-empty, and
+// free of characters (newlines, or the record separator token itself)
+// that would corrupt the on-disk snapshot file format.
 func (s *snapshotID) isValid() bool {
+	str := string(*s)
+	if str == "" {
+		return false
+	}
+	if strings.ContainsAny(str, "\n\r") {
+		return false
+	}
+	if strings.Contains(str, snapshotSeparator) {
+		return false
+	}
 	return true
 }
 
+// idOwners records, for the lifetime of the process, which test name first
+// wrote each snapshot id. It lets resolveSnapshotID and writeSnapshot
+// detect two different tests using the same literal id, which would
+// otherwise silently share one snapshot.
+var idOwners = map[snapshotID]string{}
+
+// resolveSnapshotID returns the effective snapshot id for t: t.Name() if
+// the caller passed an empty id, or id itself the first time it's used
+// this run. If id was already claimed by a different test - e.g. two
+// t.Run subtests that happen to pass the same literal id - it's namespaced
+// with t.Name() so the two tests get distinct snapshots instead of
+// silently sharing one, which matters once tests call t.Parallel().
+func resolveSnapshotID(t *testing.T, id string) snapshotID {
+	t.Helper()
+
+	if id == "" {
+		id = t.Name()
+	}
+
+	allSnapMutex.Lock()
+	defer allSnapMutex.Unlock()
+
+	owner, claimed := idOwners[snapshotID(id)]
+	if claimed && owner != t.Name() {
+		id = fmt.Sprintf("%s (%s)", id, t.Name())
+	}
+	idOwners[snapshotID(id)] = t.Name()
+
+	return snapshotID(id)
+}
+
 // snapshot represents the expected value of a test, identified by an id.
 type snapshot struct {
 	id           snapshotID
@@ -136,7 +228,7 @@ func (s snapshots) save() error {
 			return err
 		}
 
-		err = ioutil.WriteFile(path, data, 0666)
+		err = activeStore.Save(context.Background(), path, data)
 		if err != nil {
 			return err
 		}
@@ -207,22 +299,14 @@ func loadSnapshots() (err error) {
 // reloadSnapshots overwrites allSnapshots internal
 // variable with the designated snapshots file
 func reloadSnapshots() error {
-	dir, err := findOrCreateSnapshotDirectory()
-	if err != nil {
-		return err
-	}
-
-	files, err := ioutil.ReadDir(dir)
+	files, err := activeStore.List(context.Background())
 	if err != nil {
 		return err
 	}
 
-	paths := []string{}
-	for _, file := range files {
-		path := filepath.Join(dir, file.Name())
-		if isSnapshot(path) {
-			paths = append(paths, path)
-		}
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.Path
 	}
 
 	allSnapshots, err = parseSnapshotsFromPaths(paths)
@@ -234,21 +318,29 @@ func getSnapshot(id snapshotID) *snapshot {
 	if err := loadSnapshots(); err != nil {
 		panic(err)
 	}
+
+	allSnapMutex.RLock()
+	defer allSnapMutex.RUnlock()
 	return allSnapshots[id]
 }
 
-// createSnapshot creates a Snapshot.
-func createSnapshot(id snapshotID, value string) (*snapshot, error) {
-	return writeSnapshot(id, value, false)
+// createSnapshot creates a Snapshot, recording owner (t.Name()) as the
+// test that claimed id.
+func createSnapshot(id snapshotID, value, owner string) (*snapshot, error) {
+	return writeSnapshot(id, value, false, owner)
 }
 
-// updateSnapshot creates a Snapshot.
-func updateSnapshot(id snapshotID, value string) (*snapshot, error) {
-	return writeSnapshot(id, value, true)
+// updateSnapshot updates a Snapshot, recording owner (t.Name()) as the
+// test that claimed id.
+func updateSnapshot(id snapshotID, value, owner string) (*snapshot, error) {
+	return writeSnapshot(id, value, true, owner)
 }
 
-// writeSnapshot creates or updates a Snapshot.
-func writeSnapshot(id snapshotID, value string, isUpdate bool) (*snapshot, error) {
+// writeSnapshot creates or updates a Snapshot. owner identifies the test
+// writing it; if id was already claimed by a different owner, writeSnapshot
+// refuses the write instead of letting the two tests silently clobber each
+// other's snapshot, since under t.Parallel() the two writes can race.
+func writeSnapshot(id snapshotID, value string, isUpdate bool, owner string) (*snapshot, error) {
 	if !id.isValid() {
 		return nil, errInvalidSnapshotID
 	}
@@ -277,11 +369,15 @@ func writeSnapshot(id snapshotID, value string, isUpdate bool) (*snapshot, error
 	}
 
 	allSnapMutex.Lock()
+	defer allSnapMutex.Unlock()
+
+	if existingOwner, claimed := idOwners[id]; claimed && existingOwner != owner {
+		return nil, fmt.Errorf("%w: snapshot %q is already owned by test %q, conflicts with %q", errInvalidSnapshotID, id, existingOwner, owner)
+	}
+	idOwners[id] = owner
 	allSnapshots[id] = s
-	allSnapMutex.Unlock()
 
-	err = allSnapshots.save()
-	if err != nil {
+	if err := allSnapshots.save(); err != nil {
 		return nil, err
 	}
 
@@ -316,12 +412,7 @@ func parseSnapshotsFromPaths(paths []string) (snapshots, error) {
 		go func(p string) {
 			defer wg.Done()
 
-			file, err := os.Open(p)
-			if err != nil {
-				return
-			}
-
-			data, err := ioutil.ReadAll(file)
+			data, err := activeStore.Load(context.Background(), p)
 			if err != nil {
 				return
 			}
@@ -3,8 +3,16 @@
 
 package abide
 
-import "path/filepath"
+import (
+	"path/filepath"
+	"strings"
+)
 
+// isSnapshot reports whether path names a snapshot bundle, including a
+// CompressedStore bundle or sidecar (e.g. "pkg.snapshot.zip",
+// "pkg.snapshot.metadata"), not just a bare "pkg.snapshot" file -
+// filepath.Ext only ever sees the last of those extensions.
 func isSnapshot(path string) bool {
-	return filepath.Ext(path) == snapshotExt
+	base := filepath.Base(path)
+	return strings.HasSuffix(base, snapshotExt) || strings.Contains(base, snapshotExt+".")
 }
@@ -0,0 +1,91 @@
+package abide
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestSnapshotIDIsValid(t *testing.T) {
+	cases := map[string]bool{
+		"valid-id":                     true,
+		"":                             false,
+		"has\nnewline":                 false,
+		"has\r":                        false,
+		"contains" + snapshotSeparator: false,
+	}
+
+	for raw, want := range cases {
+		id := snapshotID(raw)
+		if got := id.isValid(); got != want {
+			t.Errorf("snapshotID(%q).isValid() = %v, want %v", raw, got, want)
+		}
+	}
+}
+
+func TestResolveSnapshotIDNamespacesCollisions(t *testing.T) {
+	idOwners = map[snapshotID]string{}
+
+	var first, second snapshotID
+	t.Run("subtest-a", func(t *testing.T) {
+		first = resolveSnapshotID(t, "shared")
+	})
+	t.Run("subtest-b", func(t *testing.T) {
+		second = resolveSnapshotID(t, "shared")
+	})
+
+	if first == second {
+		t.Errorf("two different tests resolved the same literal id to the same snapshotID: %q", first)
+	}
+}
+
+func TestResolveSnapshotIDEmptyUsesTestName(t *testing.T) {
+	idOwners = map[snapshotID]string{}
+
+	var id snapshotID
+	t.Run("my-subtest", func(t *testing.T) {
+		id = resolveSnapshotID(t, "")
+	})
+
+	if id != snapshotID(t.Name()+"/my-subtest") {
+		t.Errorf("resolveSnapshotID(empty) = %q, want %q", id, t.Name()+"/my-subtest")
+	}
+}
+
+// TestConcurrentSnapshotAccessIsRaceFree exercises createSnapshot/getSnapshot
+// from many goroutines at once, the actual contention point under
+// t.Parallel(). Run with -race to catch regressions in allSnapMutex's
+// coverage of allSnapshots.
+func TestConcurrentSnapshotAccessIsRaceFree(t *testing.T) {
+	orig, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(orig) })
+
+	origSnapshots := allSnapshots
+	allSnapshots, snapshotsLoaded = snapshots{}, sync.Once{}
+	t.Cleanup(func() {
+		allSnapshots = origSnapshots
+		snapshotsLoaded = sync.Once{}
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := snapshotID(fmt.Sprintf("concurrent-%d", i))
+			if _, err := createSnapshot(id, "value", fmt.Sprintf("owner-%d", i)); err != nil {
+				t.Error(err)
+				return
+			}
+			getSnapshot(id)
+		}(i)
+	}
+	wg.Wait()
+}
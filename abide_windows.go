@@ -5,6 +5,11 @@ import (
 	"strings"
 )
 
+// isSnapshot reports whether path names a snapshot bundle, including a
+// CompressedStore bundle or sidecar (e.g. "pkg.snapshot.zip",
+// "pkg.snapshot.metadata"), not just a bare "pkg.snapshot" file.
 func isSnapshot(path string) bool {
-	return strings.EqualFold(filepath.Ext(path), snapshotExt)
+	base := strings.ToLower(filepath.Base(path))
+	ext := strings.ToLower(snapshotExt)
+	return strings.HasSuffix(base, ext) || strings.Contains(base, ext+".")
 }
@@ -0,0 +1,48 @@
+package abide
+
+import "flag"
+
+// arguments holds the command-line flags abide recognizes when passed
+// after `--` to `go test`, e.g. `go test -v -- -u`.
+type arguments struct {
+	// shouldUpdate is set by the `-u` flag, and tells abide to create
+	// missing snapshots and update mismatched ones instead of failing.
+	shouldUpdate bool
+	// singleRun is set by the `-abide.single-run` flag, and disables
+	// pruning of unused snapshots, useful when running a single test or
+	// a subset of a package's tests.
+	singleRun bool
+	// reportPath is set by the `-abide.report` flag, and names a file
+	// that snapshot-mismatch events are additionally written to. A path
+	// ending in ".xml" selects JUnitReporter; anything else selects
+	// NDJSONReporter.
+	reportPath string
+}
+
+// getArguments binds abide's own flags on flag.CommandLine, which the
+// testing package parses later. It is safe to call multiple times: a flag
+// is registered (via flag.BoolVar/flag.StringVar) only if it isn't defined
+// yet; otherwise its current value is read.
+func getArguments() *arguments {
+	a := &arguments{}
+
+	if f := flag.Lookup("u"); f != nil {
+		a.shouldUpdate = f.Value.String() == "true"
+	} else {
+		flag.BoolVar(&a.shouldUpdate, "u", false, "update abide snapshots")
+	}
+
+	if f := flag.Lookup("abide.single-run"); f != nil {
+		a.singleRun = f.Value.String() == "true"
+	} else {
+		flag.BoolVar(&a.singleRun, "abide.single-run", false, "disable pruning of unused snapshots")
+	}
+
+	if f := flag.Lookup("abide.report"); f != nil {
+		a.reportPath = f.Value.String()
+	} else {
+		flag.StringVar(&a.reportPath, "abide.report", "", "write snapshot-mismatch events to this file (.xml for JUnit, otherwise NDJSON)")
+	}
+
+	return a
+}
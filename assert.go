@@ -6,11 +6,6 @@ import (
 	"io/ioutil"
 	"strings"
 	"testing"
-
-	"github.com/hexops/gotextdiff"
-	"github.com/hexops/gotextdiff/myers"
-	"github.com/hexops/gotextdiff/span"
-	"github.com/sergi/go-diff/diffmatchpatch"
 )
 
 // Assertable represents an object that can be asserted.
@@ -20,12 +15,15 @@ type Assertable interface {
 
 // Assert asserts the value of an object with implements Assertable.
 func Assert(t *testing.T, id string, a Assertable) {
+	t.Helper()
 	data := a.String()
 	createOrUpdateSnapshot(t, id, data, SnapshotGeneric)
 }
 
 // AssertReader asserts the value of an io.Reader.
 func AssertReader(t *testing.T, id string, r io.Reader) {
+	t.Helper()
+
 	data, err := ioutil.ReadAll(r)
 	if err != nil {
 		t.Fatal(err)
@@ -34,18 +32,23 @@ func AssertReader(t *testing.T, id string, r io.Reader) {
 	createOrUpdateSnapshot(t, id, string(data), SnapshotGeneric)
 }
 
-func createOrUpdateSnapshot(t *testing.T, id, data string, format SnapshotType) {
+func createOrUpdateSnapshot(t *testing.T, rawID, data string, format SnapshotType) {
+	t.Helper()
+
+	id := resolveSnapshotID(t, rawID)
+
 	var err error
-	snapshot := getSnapshot(snapshotID(id))
+	snapshot := getSnapshot(id)
 
 	if snapshot == nil {
 		if !args.shouldUpdate {
-			t.Error(newSnapshotMessage(id, data))
+			getActiveReporter().SnapshotMissing(string(id), data)
+			t.Error(newSnapshotMessage(string(id), data))
 			return
 		}
 
 		fmt.Printf("Creating snapshot `%s`\n", id)
-		snapshot, err = createSnapshot(snapshotID(id), data)
+		snapshot, err = createSnapshot(id, data, t.Name())
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -54,56 +57,47 @@ func createOrUpdateSnapshot(t *testing.T, id, data string, format SnapshotType)
 	}
 
 	snapshot.evaluated = true
-	var diff string
-	switch format {
-	case SnapshotHTTPRespJSON:
-		diff = compareResultsHTTPRequestJSON(t, snapshot.value, strings.TrimSpace(data))
-	default:
-		diff = compareResults(t, id, snapshot.value, strings.TrimSpace(data))
-	}
+	diff := compareResults(t, format, snapshot.value, strings.TrimSpace(data))
 
 	if diff != "" {
 		if snapshot != nil && args.shouldUpdate {
 			fmt.Printf("Updating snapshot `%s`\n", id)
-			_, err = updateSnapshot(snapshotID(id), data)
+			_, err = updateSnapshot(id, data, t.Name())
 			if err != nil {
 				t.Fatal(err)
 			}
 			return
 		}
 
-		t.Error(didNotMatchMessage(id, diff))
+		getActiveReporter().SnapshotDiff(string(id), snapshot.value, strings.TrimSpace(data), diff)
+		t.Error(didNotMatchMessage(string(id), diff))
 		return
 	}
 }
 
-func compareResults(t *testing.T, id, existing, new string) string {
-	c, err := getConfig()
+// compareResults looks up the Comparator registered for format and uses it
+// to diff existing against new, returning an empty string when they're
+// equal. See RegisterComparator to extend abide to a SnapshotType it
+// doesn't natively understand.
+func compareResults(t *testing.T, format SnapshotType, existing, new string) string {
+	t.Helper()
+
+	comparator := comparatorFor(format)
+
+	normalizedExisting, err := comparator.Normalize(existing)
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	if c != nil && c.UnifiedDiff {
-		edits := myers.ComputeEdits(span.URIFromPath(id), existing, new)
-		diff := gotextdiff.ToUnified("a.txt", "b.txt", existing, edits)
-		return fmt.Sprint(diff)
-	}
-
-	dmp := diffmatchpatch.New()
-	dmp.PatchMargin = 20
-	allDiffs := dmp.DiffMain(existing, new, false)
-	var nonEqualDiffs []diffmatchpatch.Diff
-	for _, diff := range allDiffs {
-		if diff.Type != diffmatchpatch.DiffEqual {
-			nonEqualDiffs = append(nonEqualDiffs, diff)
-		}
+	normalizedNew, err := comparator.Normalize(new)
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	if len(nonEqualDiffs) == 0 {
+	diff, equal := comparator.Diff(normalizedExisting, normalizedNew)
+	if equal {
 		return ""
 	}
-
-	return dmp.DiffPrettyText(allDiffs)
+	return diff
 }
 
 func didNotMatchMessage(id, diff string) string {
@@ -7,13 +7,12 @@ import (
 	"net/http/httputil"
 	"strings"
 	"testing"
-
-	"github.com/nsf/jsondiff"
-	"github.com/sergi/go-diff/diffmatchpatch"
 )
 
 // AssertHTTPResponse asserts the value of an http.Response.
 func AssertHTTPResponse(t *testing.T, id string, w *http.Response) {
+	t.Helper()
+
 	body, err := httputil.DumpResponse(w, true)
 	if err != nil {
 		t.Fatal(err)
@@ -26,6 +25,8 @@ func AssertHTTPResponse(t *testing.T, id string, w *http.Response) {
 // Intended for use when testing outgoing client requests
 // See https://golang.org/pkg/net/http/httputil/#DumpRequestOut for more
 func AssertHTTPRequestOut(t *testing.T, id string, r *http.Request) {
+	t.Helper()
+
 	body, err := httputil.DumpRequestOut(r, true)
 	if err != nil {
 		t.Fatal(err)
@@ -71,6 +72,7 @@ func (h *httpRequest) jsonBodyCleanup(c *config) error {
 		for k, v := range c.Defaults {
 			jsonIface = updateKeyValuesInMap(k, v, jsonIface)
 		}
+		jsonIface = applySkipRedact(jsonIface, c.SkipPaths, c.RedactPaths)
 	}
 
 	out, err := json.MarshalIndent(jsonIface, "", "  ")
@@ -115,6 +117,8 @@ func plainToInternalRequest(requestDump []byte) *httpRequest {
 // Intended for use when testing incoming client requests
 // See https://golang.org/pkg/net/http/httputil/#DumpRequest for more
 func AssertHTTPRequest(t *testing.T, id string, r *http.Request) {
+	t.Helper()
+
 	body, err := httputil.DumpRequest(r, true)
 	if err != nil {
 		t.Fatal(err)
@@ -126,6 +130,8 @@ func AssertHTTPRequest(t *testing.T, id string, r *http.Request) {
 // assertHTTP processes the body, this handling happens twice because there is more refactor to do but
 // it is better to have it in place for the future.
 func assertHTTP(t *testing.T, id string, body []byte, isJSON bool) {
+	t.Helper()
+
 	c, err := getConfig()
 	if err != nil {
 		t.Fatal(err)
@@ -163,57 +169,3 @@ func contentTypeIsJSON(contentType string) bool {
 	return isVendor && isJSON
 }
 
-func compareResultsHTTPRequestJSON(t *testing.T, existing, new string) string {
-	existingR := plainToInternalRequest([]byte(existing))
-	newR := plainToInternalRequest([]byte(new))
-	c, err := getConfig()
-	if err != nil {
-		t.Fatal(err)
-	}
-	existingR.configCleanup(c)
-	newR.configCleanup(c)
-	existingR.jsonBodyCleanup(c)
-	newR.jsonBodyCleanup(c)
-	// let us compare the headers in the old school ways
-	dmp := diffmatchpatch.New()
-	dmp.PatchMargin = 20
-	allDiffs := dmp.DiffMain(existingR.headerDump(), newR.headerDump(), false)
-	var nonEqualDiffs []diffmatchpatch.Diff
-	for _, diff := range allDiffs {
-		if diff.Type != diffmatchpatch.DiffEqual {
-			nonEqualDiffs = append(nonEqualDiffs, diff)
-		}
-	}
-
-	var diffSoFar string
-	if len(nonEqualDiffs) != 0 {
-		diffSoFar = dmp.DiffPrettyText(allDiffs)
-	}
-
-	opts := jsondiff.DefaultConsoleOptions()
-
-	jsonDifference, explanation := jsondiff.Compare(existingR.byteBody(), newR.byteBody(), &opts)
-	if jsonDifference == jsondiff.FullMatch {
-		return diffSoFar
-	}
-	diffSoFar += "\n"
-	switch jsonDifference {
-	case jsondiff.SupersetMatch, jsondiff.NoMatch:
-		diffSoFar += explanation
-	case jsondiff.FirstArgIsInvalidJson:
-		diffSoFar += "ERROR: Existing body is not valid JSON"
-	case jsondiff.SecondArgIsInvalidJson:
-		diffSoFar += "ERROR: New body is not valid JSON"
-	case jsondiff.BothArgsAreInvalidJson:
-		if len(existingR.body) == len(newR.body) && len(newR.body) == 0 {
-			// empty
-			return diffSoFar
-		}
-		diffSoFar += "ERROR: Neither Existing nor New bodies are valid JSON\n"
-		diffSoFar += existingR.dump()
-		diffSoFar += "\n"
-		diffSoFar += newR.dump()
-
-	}
-	return diffSoFar
-}
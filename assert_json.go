@@ -0,0 +1,168 @@
+package abide
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// jsonAsSeparator delineates the raw-JSON region of an AssertJSONAs
+// snapshot from the Go-syntax dump of the decoded struct that follows it.
+const jsonAsSeparator = "\n\n=== parsed as Go struct ===\n\n"
+
+// StrictJSONDecoding, when true, makes AssertJSONAs and AssertHTTPResponseAs
+// fail the test if the response body contains a field absent from the
+// target struct, or the target struct has an exported field, other than an
+// omitempty one, that the body's top-level object never populates. It's off
+// by default, since many response bodies
+// intentionally carry fields a given caller doesn't model.
+var StrictJSONDecoding = false
+
+// validatable is satisfied by typed response types that know how to check
+// their own invariants, e.g. types generated by go-swagger or hand-written
+// against github.com/go-playground/validator.
+type validatable interface {
+	Validate() error
+}
+
+// AssertJSONAs unmarshals body into v, validates v if it implements
+// Validate() error, and snapshots both the pretty-printed JSON body and a
+// Go-syntax dump of v, so that a change to the wire format and a change to
+// the typed contract are reported as distinct diffs.
+func AssertJSONAs(t *testing.T, id string, body []byte, v interface{}) {
+	t.Helper()
+	assertJSONAs(t, id, body, v)
+}
+
+// AssertHTTPResponseAs is AssertJSONAs for an *http.Response's body.
+func AssertHTTPResponseAs(t *testing.T, id string, w *http.Response, v interface{}) {
+	t.Helper()
+
+	body, err := ioutil.ReadAll(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertJSONAs(t, id, body, v)
+}
+
+func assertJSONAs(t *testing.T, id string, body []byte, v interface{}) {
+	t.Helper()
+
+	if err := decodeJSONAs(body, v); err != nil {
+		t.Fatal(err)
+	}
+
+	if val, ok := v.(validatable); ok {
+		if err := val.Validate(); err != nil {
+			t.Fatalf("%q: %s", id, err)
+		}
+	}
+
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, body, "", "  "); err != nil {
+		t.Fatal(err)
+	}
+
+	data := pretty.String() + jsonAsSeparator + goSyntaxDump(v)
+	createOrUpdateSnapshot(t, id, data, SnapshotJSONAs)
+}
+
+// decodeJSONAs unmarshals body into v, optionally enforcing
+// StrictJSONDecoding in both directions: unknown fields in body, and
+// struct fields body never populates.
+func decodeJSONAs(body []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if StrictJSONDecoding {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("decoding JSON into %T: %w", v, err)
+	}
+
+	if !StrictJSONDecoding {
+		return nil
+	}
+	return checkNoMissingFields(body, v)
+}
+
+// checkNoMissingFields reports an error if v has an exported field, keyed by
+// its json tag name or, untagged, by its Go field name, with no
+// corresponding key in body's top-level JSON object, the inverse of
+// DisallowUnknownFields. Fields tagged "-" or omitempty are not required.
+func checkNoMissingFields(body []byte, v interface{}) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		// Not a JSON object at the top level; nothing to check here.
+		return nil
+	}
+
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue // unexported; encoding/json never populates it
+		}
+		parts := strings.Split(f.Tag.Get("json"), ",")
+		tag := parts[0]
+		switch tag {
+		case "-":
+			continue
+		case "":
+			tag = f.Name
+		}
+		if _, ok := raw[tag]; !ok {
+			if hasOmitempty(parts) {
+				continue
+			}
+			return fmt.Errorf("field %q on %T has no matching key in the response body", tag, v)
+		}
+	}
+
+	return nil
+}
+
+// hasOmitempty reports whether a json struct tag's comma-separated options
+// (as split by strings.Split, so options[0] is the tag name itself) include
+// "omitempty".
+func hasOmitempty(options []string) bool {
+	for _, opt := range options[1:] {
+		if opt == "omitempty" {
+			return true
+		}
+	}
+	return false
+}
+
+// goSyntaxDump renders v as Go syntax, dereferencing pointers so the dump
+// reads as a value literal rather than a memory address. Go's %#v verb
+// already prints map keys in sorted order, so the dump does not depend on
+// map iteration order. Only v's own pointer is dereferenced: pointers nested
+// inside v are printed by %#v as memory addresses.
+func goSyntaxDump(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	return fmt.Sprintf("%#v", rv.Interface())
+}
+
+func splitJSONAs(s string) (body, dump string) {
+	parts := strings.SplitN(s, jsonAsSeparator, 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return parts[0], ""
+}
@@ -0,0 +1,63 @@
+package abide
+
+import "testing"
+
+type widget struct {
+	Name  string `json:"name"`
+	Price int    `json:"price"`
+	Notes string `json:"notes,omitempty"`
+}
+
+func TestDecodeJSONAsStrictMissingField(t *testing.T) {
+	orig := StrictJSONDecoding
+	StrictJSONDecoding = true
+	defer func() { StrictJSONDecoding = orig }()
+
+	var w widget
+	err := decodeJSONAs([]byte(`{"name":"widget"}`), &w)
+	if err == nil {
+		t.Fatal("expected an error for a non-omitempty struct field the body never populates")
+	}
+}
+
+func TestDecodeJSONAsStrictOmitemptyFieldAllowedMissing(t *testing.T) {
+	orig := StrictJSONDecoding
+	StrictJSONDecoding = true
+	defer func() { StrictJSONDecoding = orig }()
+
+	var w widget
+	err := decodeJSONAs([]byte(`{"name":"widget","price":100}`), &w)
+	if err != nil {
+		t.Fatalf("unexpected error for a missing omitempty field: %s", err)
+	}
+}
+
+func TestDecodeJSONAsStrictNoMissingField(t *testing.T) {
+	orig := StrictJSONDecoding
+	StrictJSONDecoding = true
+	defer func() { StrictJSONDecoding = orig }()
+
+	var w widget
+	err := decodeJSONAs([]byte(`{"name":"widget","price":100,"notes":"n/a"}`), &w)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestDecodeJSONAsNotStrict(t *testing.T) {
+	orig := StrictJSONDecoding
+	StrictJSONDecoding = false
+	defer func() { StrictJSONDecoding = orig }()
+
+	var w widget
+	if err := decodeJSONAs([]byte(`{"name":"widget"}`), &w); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestSplitJSONAs(t *testing.T) {
+	body, dump := splitJSONAs("body" + jsonAsSeparator + "dump")
+	if body != "body" || dump != "dump" {
+		t.Fatalf("splitJSONAs = (%q, %q)", body, dump)
+	}
+}
@@ -0,0 +1,217 @@
+package abide
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+	"github.com/nsf/jsondiff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Comparator normalizes and diffs snapshot content for a SnapshotType.
+type Comparator interface {
+	// Normalize canonicalizes data before it's compared. An error here
+	// fails the test, the same as a failure to read the snapshot file.
+	Normalize(data string) (string, error)
+	// Diff compares existing against new and reports whether they're
+	// equal. diff is ignored when equal is true.
+	Diff(existing, new string) (diff string, equal bool)
+}
+
+// comparators holds the Comparator registered for each SnapshotType.
+// SnapshotGeneric's entry is also the fallback for any type with nothing
+// registered. comparatorsMutex guards it, since RegisterComparator can be
+// called from a test that runs in parallel with others asserting via
+// comparatorFor.
+var (
+	comparators = map[SnapshotType]Comparator{
+		SnapshotGeneric:      diffmatchpatchComparator{},
+		SnapshotHTTPRespJSON: httpJSONComparator{},
+		SnapshotJSONAs:       jsonAsComparator{},
+	}
+	comparatorsMutex sync.RWMutex
+)
+
+// RegisterComparator associates a Comparator with a SnapshotType, replacing
+// whatever was registered for it before, including one of abide's built-ins.
+func RegisterComparator(t SnapshotType, c Comparator) {
+	comparatorsMutex.Lock()
+	defer comparatorsMutex.Unlock()
+	comparators[t] = c
+}
+
+// comparatorFor returns the Comparator registered for t, falling back to
+// the generic comparator if nothing is registered.
+func comparatorFor(t SnapshotType) Comparator {
+	comparatorsMutex.RLock()
+	defer comparatorsMutex.RUnlock()
+	if c, ok := comparators[t]; ok {
+		return c
+	}
+	return comparators[SnapshotGeneric]
+}
+
+// diffmatchpatchComparator is abide's original, format-agnostic comparator:
+// a byte-level diff via diffmatchpatch, or gotextdiff's unified output when
+// config.UnifiedDiff is set.
+type diffmatchpatchComparator struct{}
+
+func (diffmatchpatchComparator) Normalize(data string) (string, error) {
+	return data, nil
+}
+
+func (diffmatchpatchComparator) Diff(existing, new string) (string, bool) {
+	c, _ := getConfig()
+	if c != nil && c.UnifiedDiff {
+		return UnifiedDiffComparator{}.Diff(existing, new)
+	}
+	return diffMatchPatchDiff(existing, new)
+}
+
+// UnifiedDiffComparator renders a diff in unified-diff format via
+// gotextdiff, regardless of the UnifiedDiff config flag. Register it
+// explicitly for a SnapshotType to force unified-diff output independent
+// of global config.
+type UnifiedDiffComparator struct{}
+
+func (UnifiedDiffComparator) Normalize(data string) (string, error) {
+	return data, nil
+}
+
+func (UnifiedDiffComparator) Diff(existing, new string) (string, bool) {
+	if existing == new {
+		return "", true
+	}
+	edits := myers.ComputeEdits(span.URIFromPath("a.txt"), existing, new)
+	diff := gotextdiff.ToUnified("a.txt", "b.txt", existing, edits)
+	return fmt.Sprint(diff), false
+}
+
+func diffMatchPatchDiff(existing, new string) (string, bool) {
+	dmp := diffmatchpatch.New()
+	dmp.PatchMargin = 20
+	allDiffs := dmp.DiffMain(existing, new, false)
+
+	for _, d := range allDiffs {
+		if d.Type != diffmatchpatch.DiffEqual {
+			return dmp.DiffPrettyText(allDiffs), false
+		}
+	}
+
+	return "", true
+}
+
+// httpJSONComparator is abide's original HTTP+JSON comparator: a
+// diffmatchpatch comparison of the header block, plus a jsondiff semantic
+// comparison of the body so formatting-only JSON differences don't show up
+// as a mismatch.
+type httpJSONComparator struct{}
+
+func (httpJSONComparator) Normalize(data string) (string, error) {
+	return data, nil
+}
+
+func (httpJSONComparator) Diff(existing, new string) (string, bool) {
+	existingR := plainToInternalRequest([]byte(existing))
+	newR := plainToInternalRequest([]byte(new))
+
+	c, _ := getConfig()
+	existingR.configCleanup(c)
+	newR.configCleanup(c)
+	_ = existingR.jsonBodyCleanup(c)
+	_ = newR.jsonBodyCleanup(c)
+
+	headerDiff, headerEqual := diffMatchPatchDiff(existingR.headerDump(), newR.headerDump())
+
+	opts := jsondiff.DefaultConsoleOptions()
+	jsonDifference, explanation := jsondiff.Compare(existingR.byteBody(), newR.byteBody(), &opts)
+
+	if headerEqual && jsonDifference == jsondiff.FullMatch {
+		return "", true
+	}
+
+	diff := headerDiff
+	switch jsonDifference {
+	case jsondiff.FullMatch:
+	case jsondiff.SupersetMatch, jsondiff.NoMatch:
+		diff += "\n" + explanation
+	case jsondiff.FirstArgIsInvalidJson:
+		diff += "\nERROR: Existing body is not valid JSON"
+	case jsondiff.SecondArgIsInvalidJson:
+		diff += "\nERROR: New body is not valid JSON"
+	case jsondiff.BothArgsAreInvalidJson:
+		if len(existingR.body) == 0 && len(newR.body) == 0 {
+			return diff, headerEqual
+		}
+		diff += "\nERROR: Neither Existing nor New bodies are valid JSON\n"
+		diff += existingR.dump() + "\n" + newR.dump()
+	}
+
+	return diff, false
+}
+
+// JSONSemanticComparator is a pure-JSON Comparator: it ignores formatting
+// and key order entirely and compares via jsondiff's semantic modes.
+// Register it for a custom SnapshotType to compare JSON bodies that aren't
+// wrapped in an HTTP request/response dump.
+type JSONSemanticComparator struct {
+	// Mode controls how strictly the comparison treats fields missing from
+	// new: jsondiff.FullMatch (default, exact), or
+	// jsondiff.SupersetMatch to also accept new when existing is a superset
+	// of it, i.e. existing carries fields new doesn't have.
+	Mode jsondiff.Difference
+}
+
+func (JSONSemanticComparator) Normalize(data string) (string, error) {
+	return data, nil
+}
+
+func (j JSONSemanticComparator) Diff(existing, new string) (string, bool) {
+	opts := jsondiff.DefaultConsoleOptions()
+	difference, explanation := jsondiff.Compare([]byte(existing), []byte(new), &opts)
+
+	mode := j.Mode
+	if mode == 0 {
+		mode = jsondiff.FullMatch
+	}
+
+	if difference == jsondiff.FullMatch || (mode == jsondiff.SupersetMatch && difference == jsondiff.SupersetMatch) {
+		return "", true
+	}
+
+	return explanation, false
+}
+
+// jsonAsComparator diffs the two halves of an AssertJSONAs snapshot
+// independently: the raw JSON body, and the Go-syntax dump of the decoded
+// struct, so a change to one is reported without the noise of a re-diff of
+// the other.
+type jsonAsComparator struct{}
+
+func (jsonAsComparator) Normalize(data string) (string, error) {
+	return data, nil
+}
+
+func (jsonAsComparator) Diff(existing, new string) (string, bool) {
+	existingBody, existingDump := splitJSONAs(existing)
+	newBody, newDump := splitJSONAs(new)
+
+	var diff string
+	if d, equal := diffMatchPatchDiff(existingBody, newBody); !equal {
+		diff += "## raw JSON body differs:\n" + d
+	}
+	if d, equal := diffMatchPatchDiff(existingDump, newDump); !equal {
+		if diff != "" {
+			diff += "\n\n"
+		}
+		diff += "## parsed Go struct differs:\n" + d
+	}
+
+	if diff == "" {
+		return "", true
+	}
+	return diff, false
+}
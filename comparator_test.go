@@ -0,0 +1,64 @@
+package abide
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/nsf/jsondiff"
+)
+
+func TestComparatorForFallsBackToGeneric(t *testing.T) {
+	if _, ok := comparatorFor(SnapshotType("unregistered")).(diffmatchpatchComparator); !ok {
+		t.Errorf("comparatorFor(unregistered type) did not fall back to the generic comparator")
+	}
+}
+
+func TestRegisterComparator(t *testing.T) {
+	custom := JSONSemanticComparator{}
+	t.Cleanup(func() { delete(comparators, SnapshotType("custom")) })
+
+	RegisterComparator(SnapshotType("custom"), custom)
+
+	if comparatorFor(SnapshotType("custom")) != Comparator(custom) {
+		t.Errorf("comparatorFor did not return the registered comparator")
+	}
+}
+
+func TestJSONSemanticComparator(t *testing.T) {
+	c := JSONSemanticComparator{}
+
+	if _, equal := c.Diff(`{"a":1,"b":2}`, `{"b":2,"a":1}`); !equal {
+		t.Error("expected semantically identical JSON with different key order to be equal")
+	}
+
+	if _, equal := c.Diff(`{"a":1}`, `{"a":2}`); equal {
+		t.Error("expected a changed value to be reported as a difference")
+	}
+}
+
+// TestConcurrentRegisterComparatorIsRaceFree exercises RegisterComparator
+// and comparatorFor from many goroutines at once, the actual contention
+// point once tests calling either run in parallel. Run with -race to catch
+// regressions in comparatorsMutex's coverage of comparators.
+func TestConcurrentRegisterComparatorIsRaceFree(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			typ := SnapshotType(fmt.Sprintf("concurrent-%d", i))
+			RegisterComparator(typ, diffmatchpatchComparator{})
+			comparatorFor(typ)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestJSONSemanticComparatorSupersetMode(t *testing.T) {
+	c := JSONSemanticComparator{Mode: jsondiff.SupersetMatch}
+
+	if _, equal := c.Diff(`{"a":1,"b":2}`, `{"a":1}`); !equal {
+		t.Error("expected SupersetMatch mode to allow existing to carry fields new doesn't")
+	}
+}
@@ -0,0 +1,95 @@
+package abide
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+// configFileName is the name of the optional, per-project configuration
+// file abide looks for in the current working directory.
+const configFileName = ".abide.yml"
+
+// config represents the optional project-level configuration read from
+// configFileName. It is cached after the first successful load.
+type config struct {
+	// Defaults maps a key to a stable replacement value for any field
+	// with a matching key, anywhere in the body.
+	Defaults map[string]string `yaml:"defaults"`
+	// UnifiedDiff selects gotextdiff's unified-diff output instead of
+	// the default diffmatchpatch pretty text when comparing snapshots.
+	UnifiedDiff bool `yaml:"unifiedDiff"`
+	// SkipPaths are path expressions (see path.go) identifying fields to
+	// drop entirely from the snapshot, e.g. "data.users[*].created_at".
+	SkipPaths []string `yaml:"skip"`
+	// RedactPaths are path expressions identifying fields to redact.
+	RedactPaths []string `yaml:"redact"`
+}
+
+var (
+	loadedConfig       *config
+	configLoaded       bool
+	configMutex        sync.Mutex
+	runtimeSkipPaths   []string
+	runtimeRedactPaths []string
+	// runtimePathsMutex guards runtimeSkipPaths and runtimeRedactPaths,
+	// which SkipFields/RedactFields append to (scoped back out via
+	// t.Cleanup) and getConfig reads on every assertion.
+	runtimePathsMutex sync.Mutex
+)
+
+// getConfig loads and caches the project configuration, if any. A missing
+// config file is not an error; getConfig simply returns a config with only
+// the runtime-registered Skip/Redact paths applied.
+func getConfig() (*config, error) {
+	configMutex.Lock()
+	if !configLoaded {
+		loaded, err := loadConfig(configFileName)
+		if err != nil {
+			configMutex.Unlock()
+			return nil, err
+		}
+		loadedConfig, configLoaded = loaded, true
+	}
+	c := loadedConfig
+	configMutex.Unlock()
+
+	if c == nil {
+		c = &config{}
+	}
+
+	runtimePathsMutex.Lock()
+	skipPaths := append([]string{}, runtimeSkipPaths...)
+	redactPaths := append([]string{}, runtimeRedactPaths...)
+	runtimePathsMutex.Unlock()
+
+	if len(skipPaths) > 0 || len(redactPaths) > 0 {
+		merged := *c
+		merged.SkipPaths = append(append([]string{}, c.SkipPaths...), skipPaths...)
+		merged.RedactPaths = append(append([]string{}, c.RedactPaths...), redactPaths...)
+		c = &merged
+	}
+
+	return c, nil
+}
+
+// loadConfig reads and parses the configuration file at path. It returns a
+// nil config, and no error, if the file does not exist.
+func loadConfig(path string) (*config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	c := &config{}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
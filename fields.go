@@ -0,0 +1,41 @@
+package abide
+
+import "testing"
+
+// SkipFields registers path expressions (e.g. "data.users[*].id",
+// "**.password") identifying fields to drop from snapshots entirely,
+// extending whatever is already configured via configFileName. The paths
+// apply only for the duration of t: they're automatically unregistered via
+// t.Cleanup when t completes, so they don't leak into later tests in the
+// same binary. Like t.Setenv, this mutates process-global state and so
+// must not be called from a test using t.Parallel().
+func SkipFields(t *testing.T, paths ...string) {
+	t.Helper()
+	registerRuntimePaths(t, &runtimeSkipPaths, paths)
+}
+
+// RedactFields registers path expressions identifying fields whose value
+// should be replaced with redactedPlaceholder rather than removed. See
+// SkipFields for the path expression syntax, scoping, and t.Parallel()
+// caveat.
+func RedactFields(t *testing.T, paths ...string) {
+	t.Helper()
+	registerRuntimePaths(t, &runtimeRedactPaths, paths)
+}
+
+// registerRuntimePaths appends paths to *dst and schedules their removal
+// when t completes.
+func registerRuntimePaths(t *testing.T, dst *[]string, paths []string) {
+	t.Helper()
+
+	runtimePathsMutex.Lock()
+	start := len(*dst)
+	*dst = append(*dst, paths...)
+	runtimePathsMutex.Unlock()
+
+	t.Cleanup(func() {
+		runtimePathsMutex.Lock()
+		*dst = (*dst)[:start]
+		runtimePathsMutex.Unlock()
+	})
+}
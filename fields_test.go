@@ -0,0 +1,80 @@
+package abide
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestSkipFieldsScopedToTest(t *testing.T) {
+	t.Run("inner", func(t *testing.T) {
+		SkipFields(t, "inner.only")
+
+		c, err := getConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !containsString(c.SkipPaths, "inner.only") {
+			t.Errorf("getConfig().SkipPaths = %v, want it to contain %q while the registering test is still running", c.SkipPaths, "inner.only")
+		}
+	})
+
+	c, err := getConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if containsString(c.SkipPaths, "inner.only") {
+		t.Errorf("getConfig().SkipPaths = %v, want %q gone once the registering subtest completed", c.SkipPaths, "inner.only")
+	}
+}
+
+func TestRedactFieldsScopedToTest(t *testing.T) {
+	t.Run("inner", func(t *testing.T) {
+		RedactFields(t, "inner.secret")
+
+		c, err := getConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !containsString(c.RedactPaths, "inner.secret") {
+			t.Errorf("getConfig().RedactPaths = %v, want it to contain %q while the registering test is still running", c.RedactPaths, "inner.secret")
+		}
+	})
+
+	c, err := getConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if containsString(c.RedactPaths, "inner.secret") {
+		t.Errorf("getConfig().RedactPaths = %v, want %q gone once the registering subtest completed", c.RedactPaths, "inner.secret")
+	}
+}
+
+// TestConcurrentSkipFieldsIsRaceFree exercises SkipFields/getConfig from
+// many goroutines at once. Run with -race to catch regressions in
+// runtimePathsMutex's coverage of runtimeSkipPaths/runtimeRedactPaths.
+func TestConcurrentSkipFieldsIsRaceFree(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			t.Run(fmt.Sprintf("concurrent-%d", i), func(t *testing.T) {
+				SkipFields(t, fmt.Sprintf("field-%d", i))
+				if _, err := getConfig(); err != nil {
+					t.Error(err)
+				}
+			})
+		}(i)
+	}
+	wg.Wait()
+}
+
+func containsString(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
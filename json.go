@@ -10,14 +10,12 @@ func updateKeyValuesInMap(key string, value interface{}, m map[string]interface{
 func updateMap(key string, value interface{}, m map[string]interface{}) map[string]interface{} {
 	for k, v := range m {
 		switch s := v.(type) {
-		// If slice, iterate through each entry and call updateMap
-		// only if it's a map[string]interface{}.
+		// If slice, iterate through each entry and recurse into whatever
+		// it holds, maps and nested slices alike; scalar elements are left
+		// unchanged.
 		case []interface{}:
 			for i := range s {
-				switch s[i].(type) {
-				case map[string]interface{}:
-					v.([]interface{})[i] = updateMap(key, value, v.([]interface{})[i].(map[string]interface{}))
-				}
+				s[i] = updateSliceElement(key, value, s[i])
 			}
 		case map[string]interface{}:
 			m[k] = updateMap(key, value, s)
@@ -30,3 +28,20 @@ func updateMap(key string, value interface{}, m map[string]interface{}) map[stri
 
 	return m
 }
+
+// updateSliceElement applies updateMap/updateSliceElement recursively to a
+// single slice element, regardless of whether it's itself a map, a nested
+// slice, or a scalar.
+func updateSliceElement(key string, value interface{}, elem interface{}) interface{} {
+	switch e := elem.(type) {
+	case map[string]interface{}:
+		return updateMap(key, value, e)
+	case []interface{}:
+		for i := range e {
+			e[i] = updateSliceElement(key, value, e[i])
+		}
+		return e
+	default:
+		return elem
+	}
+}
@@ -0,0 +1,169 @@
+package abide
+
+import (
+	"strconv"
+	"strings"
+)
+
+// redactedPlaceholder replaces the value of any field matched by a redact
+// path expression.
+const redactedPlaceholder = "<redacted>"
+
+// pathSegment is one dot- or bracket-delimited component of a path
+// expression, e.g. "data.users[*].id" parses into {key:"data"},
+// {key:"users"}, {wildcard:true}, {key:"id"}.
+type pathSegment struct {
+	key       string
+	index     int
+	wildcard  bool // matched by [*]
+	recursive bool // matched by **, matches any number of intervening levels
+}
+
+// parsePath splits a path expression into its component segments. [*]
+// matches every slice element, [N] matches only index N, and a standalone
+// "**" segment matches any number of intervening levels, e.g. "**.password".
+func parsePath(path string) []pathSegment {
+	var segments []pathSegment
+
+	for _, part := range strings.Split(path, ".") {
+		if part == "**" {
+			segments = append(segments, pathSegment{recursive: true})
+			continue
+		}
+
+		key := part
+		var brackets []string
+		if i := strings.IndexByte(part, '['); i >= 0 {
+			key = part[:i]
+			for _, b := range strings.Split(part[i:], "[") {
+				if b == "" {
+					continue
+				}
+				brackets = append(brackets, strings.TrimSuffix(b, "]"))
+			}
+		}
+
+		if key != "" {
+			segments = append(segments, pathSegment{key: key})
+		}
+
+		for _, b := range brackets {
+			if b == "*" {
+				segments = append(segments, pathSegment{wildcard: true})
+				continue
+			}
+			n, err := strconv.Atoi(b)
+			if err != nil {
+				continue
+			}
+			segments = append(segments, pathSegment{index: n})
+		}
+	}
+
+	return segments
+}
+
+// skippedField tells the parent container that called applyPathOp to
+// remove the matched field entirely, rather than replace its value.
+type skippedField struct{}
+
+// applySkipRedact removes every field matched by a skip path expression and
+// replaces the value of every field matched by a redact path expression
+// with redactedPlaceholder, traversing map[string]interface{} and
+// []interface{} transparently.
+func applySkipRedact(m map[string]interface{}, skipPaths, redactPaths []string) map[string]interface{} {
+	for _, p := range skipPaths {
+		applyPathOp(m, parsePath(p), true)
+	}
+	for _, p := range redactPaths {
+		applyPathOp(m, parsePath(p), false)
+	}
+	return m
+}
+
+// applyPathOp applies one parsed path expression to value, either removing
+// the matched field (skip) or replacing its value with a placeholder.
+func applyPathOp(value interface{}, segments []pathSegment, skip bool) interface{} {
+	if len(segments) == 0 {
+		if skip {
+			return skippedField{}
+		}
+		return redactedPlaceholder
+	}
+
+	seg := segments[0]
+	rest := segments[1:]
+
+	switch seg.recursive {
+	case true:
+		return applyRecursive(value, rest, skip)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if seg.key == "" {
+			return v
+		}
+		child, ok := v[seg.key]
+		if !ok {
+			return v
+		}
+		result := applyPathOp(child, rest, skip)
+		if _, isSkipped := result.(skippedField); isSkipped {
+			delete(v, seg.key)
+		} else {
+			v[seg.key] = result
+		}
+		return v
+	case []interface{}:
+		if seg.key != "" {
+			return v
+		}
+		kept := v[:0]
+		for i, item := range v {
+			if !seg.wildcard && seg.index != i {
+				kept = append(kept, item)
+				continue
+			}
+			result := applyPathOp(item, rest, skip)
+			if _, isSkipped := result.(skippedField); isSkipped {
+				continue
+			}
+			kept = append(kept, result)
+		}
+		return kept
+	default:
+		return v
+	}
+}
+
+// applyRecursive implements "**" by matching rest at the current level and
+// at every level of nesting below it.
+func applyRecursive(value interface{}, rest []pathSegment, skip bool) interface{} {
+	value = applyPathOp(value, rest, skip)
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			result := applyRecursive(child, rest, skip)
+			if _, isSkipped := result.(skippedField); isSkipped {
+				delete(v, k)
+			} else {
+				v[k] = result
+			}
+		}
+		return v
+	case []interface{}:
+		kept := v[:0]
+		for _, item := range v {
+			result := applyRecursive(item, rest, skip)
+			if _, isSkipped := result.(skippedField); isSkipped {
+				continue
+			}
+			kept = append(kept, result)
+		}
+		return kept
+	default:
+		return v
+	}
+}
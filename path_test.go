@@ -0,0 +1,85 @@
+package abide
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	cases := map[string][]pathSegment{
+		"data.users[*].id": {
+			{key: "data"}, {key: "users"}, {wildcard: true}, {key: "id"},
+		},
+		"data.items[0].token": {
+			{key: "data"}, {key: "items"}, {index: 0}, {key: "token"},
+		},
+		"**.password": {
+			{recursive: true}, {key: "password"},
+		},
+	}
+
+	for path, want := range cases {
+		got := parsePath(path)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("parsePath(%q) = %+v, want %+v", path, got, want)
+		}
+	}
+}
+
+func TestApplySkipRedactSkip(t *testing.T) {
+	m := map[string]interface{}{
+		"data": map[string]interface{}{
+			"users": []interface{}{
+				map[string]interface{}{"id": "1", "created_at": "now"},
+				map[string]interface{}{"id": "2", "created_at": "later"},
+			},
+		},
+	}
+
+	applySkipRedact(m, []string{"data.users[*].created_at"}, nil)
+
+	users := m["data"].(map[string]interface{})["users"].([]interface{})
+	for _, u := range users {
+		if _, ok := u.(map[string]interface{})["created_at"]; ok {
+			t.Errorf("created_at was not removed: %+v", u)
+		}
+	}
+}
+
+func TestApplySkipRedactRedact(t *testing.T) {
+	m := map[string]interface{}{
+		"user": map[string]interface{}{
+			"password":  "hunter2",
+			"nested":    map[string]interface{}{"password": "hunter3"},
+			"unrelated": "kept",
+		},
+	}
+
+	applySkipRedact(m, nil, []string{"**.password"})
+
+	user := m["user"].(map[string]interface{})
+	if user["password"] != redactedPlaceholder {
+		t.Errorf("user.password = %v, want %v", user["password"], redactedPlaceholder)
+	}
+	nested := user["nested"].(map[string]interface{})
+	if nested["password"] != redactedPlaceholder {
+		t.Errorf("user.nested.password = %v, want %v", nested["password"], redactedPlaceholder)
+	}
+	if user["unrelated"] != "kept" {
+		t.Errorf("unrelated field was modified: %v", user["unrelated"])
+	}
+}
+
+func TestApplySkipRedactScalarSlice(t *testing.T) {
+	m := map[string]interface{}{
+		"tags": []interface{}{"keep", "drop-me", "keep"},
+	}
+
+	applySkipRedact(m, []string{"tags[1]"}, nil)
+
+	tags := m["tags"].([]interface{})
+	want := []interface{}{"keep", "keep"}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("tags = %+v, want %+v", tags, want)
+	}
+}
@@ -0,0 +1,220 @@
+package abide
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Reporter receives snapshot-mismatch events as they're discovered, so
+// teams can post-process outcomes (e.g. auto-opening a PR to update
+// snapshots, or feeding a CI dashboard) without scraping go test stdout.
+type Reporter interface {
+	// SnapshotMissing is called when id has no existing snapshot and
+	// -u wasn't passed, so the test is about to fail.
+	SnapshotMissing(id, body string)
+	// SnapshotDiff is called when id's existing snapshot doesn't match
+	// the new value, so the test is about to fail.
+	SnapshotDiff(id, existing, new, diff string)
+	// SnapshotUnused is called during Cleanup/CleanupOrFail for every
+	// snapshot that was never evaluated by a test this run. It is not
+	// called when -abide.single-run was passed, nor by Cleanup without -u.
+	SnapshotUnused(id string)
+}
+
+// Flusher is implemented by Reporters that buffer events and need a final
+// call to write them out, such as JUnitReporter. Cleanup and CleanupOrFail
+// call Flush on activeReporter if it implements Flusher.
+type Flusher interface {
+	Flush() error
+}
+
+// activeReporter is the Reporter every snapshot-mismatch event is sent to,
+// in addition to the usual t.Error/t.Fatal failure. It defaults to
+// TextReporter, which does nothing extra, preserving today's output.
+var (
+	activeReporter    Reporter = TextReporter{}
+	reporterSetByUser bool
+	autoReporterOnce  sync.Once
+)
+
+// SetReporter overrides the Reporter abide reports snapshot-mismatch
+// events to. Call it before any assertions run, e.g. from TestMain.
+func SetReporter(r Reporter) {
+	activeReporter = r
+	reporterSetByUser = true
+}
+
+// getActiveReporter returns the Reporter to use, selecting one from
+// -abide.report the first time it's needed. This can't happen in init()
+// like the rest of arguments.go's flags are consumed: flag.Parse runs
+// later, inside testing.Main, so args.reportPath is still unset at init
+// time.
+func getActiveReporter() Reporter {
+	autoReporterOnce.Do(func() {
+		if reporterSetByUser || args.reportPath == "" {
+			return
+		}
+		if strings.HasSuffix(args.reportPath, ".xml") {
+			activeReporter = NewJUnitReporter(args.reportPath)
+		} else {
+			activeReporter = NewNDJSONReporter(args.reportPath)
+		}
+	})
+	return activeReporter
+}
+
+func flushActiveReporter() error {
+	if f, ok := getActiveReporter().(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// TextReporter is the zero-config default: abide's existing plain-text
+// t.Error/t.Fatal output already describes every event, so TextReporter has
+// nothing further to do.
+type TextReporter struct{}
+
+func (TextReporter) SnapshotMissing(id, body string)             {}
+func (TextReporter) SnapshotDiff(id, existing, new, diff string) {}
+func (TextReporter) SnapshotUnused(id string)                    {}
+
+// ndjsonEvent is one line written by NDJSONReporter.
+type ndjsonEvent struct {
+	Event        string `json:"event"`
+	ID           string `json:"id"`
+	Package      string `json:"package"`
+	Diff         string `json:"diff,omitempty"`
+	ExistingHash string `json:"existing_hash,omitempty"`
+	NewHash      string `json:"new_hash,omitempty"`
+}
+
+// NDJSONReporter appends one JSON object per event to a file, selected with
+// `-abide.report=/path/to/file.ndjson`. Each line has fields
+// {event, id, package}, plus whichever of {diff, existing_hash, new_hash}
+// the event carries: all three for "diff", new_hash for "missing", none
+// for "unused".
+type NDJSONReporter struct {
+	Path string
+}
+
+// NewNDJSONReporter returns an NDJSONReporter writing to path.
+func NewNDJSONReporter(path string) *NDJSONReporter {
+	return &NDJSONReporter{Path: path}
+}
+
+func (r *NDJSONReporter) write(e ndjsonEvent) {
+	pkg, _ := getTestingPackage()
+	e.Package = pkg
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+
+	f, err := os.OpenFile(r.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	f.Write(data)
+}
+
+func (r *NDJSONReporter) SnapshotMissing(id, body string) {
+	r.write(ndjsonEvent{Event: "missing", ID: id, NewHash: hashString(body)})
+}
+
+func (r *NDJSONReporter) SnapshotDiff(id, existing, new, diff string) {
+	r.write(ndjsonEvent{
+		Event:        "diff",
+		ID:           id,
+		Diff:         diff,
+		ExistingHash: hashString(existing),
+		NewHash:      hashString(new),
+	})
+}
+
+func (r *NDJSONReporter) SnapshotUnused(id string) {
+	r.write(ndjsonEvent{Event: "unused", ID: id})
+}
+
+func hashString(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// junitTestsuite/junitTestcase/junitFailure mirror the subset of the JUnit
+// XML schema CI systems look for: one <testcase> per reported event, with
+// a <failure> child for anything that isn't a clean pass.
+type junitTestsuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Testcases []junitTestcase `xml:"testcase"`
+}
+
+type junitTestcase struct {
+	Name    string        `xml:"name,attr"`
+	Failure *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// JUnitReporter buffers every event and writes a single JUnit XML document
+// to Path when Flush is called, so CI systems can surface each snapshot
+// mismatch as its own failing test case.
+type JUnitReporter struct {
+	Path string
+
+	suite junitTestsuite
+}
+
+// NewJUnitReporter returns a JUnitReporter writing to path on Flush.
+func NewJUnitReporter(path string) *JUnitReporter {
+	return &JUnitReporter{Path: path, suite: junitTestsuite{Name: "abide"}}
+}
+
+func (r *JUnitReporter) SnapshotMissing(id, body string) {
+	r.fail(id, "missing snapshot", body)
+}
+
+func (r *JUnitReporter) SnapshotDiff(id, existing, new, diff string) {
+	r.fail(id, "snapshot mismatch", diff)
+}
+
+func (r *JUnitReporter) SnapshotUnused(id string) {
+	r.fail(id, "unused snapshot", fmt.Sprintf("snapshot %q was never evaluated", id))
+}
+
+func (r *JUnitReporter) fail(id, message, body string) {
+	r.suite.Tests++
+	r.suite.Failures++
+	r.suite.Testcases = append(r.suite.Testcases, junitTestcase{
+		Name:    id,
+		Failure: &junitFailure{Message: message, Body: body},
+	})
+}
+
+// Flush writes the accumulated testsuite to r.Path as JUnit XML.
+func (r *JUnitReporter) Flush() error {
+	data, err := xml.MarshalIndent(r.suite, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append([]byte(xml.Header), data...)
+
+	return ioutil.WriteFile(r.Path, data, 0666)
+}
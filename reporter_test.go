@@ -0,0 +1,61 @@
+package abide
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// resetActiveReporter restores the reporter-selection globals so each test
+// gets a fresh, unselected state; getActiveReporter only resolves once per
+// process otherwise.
+func resetActiveReporter(t *testing.T) {
+	t.Helper()
+	activeReporter = TextReporter{}
+	reporterSetByUser = false
+	autoReporterOnce = sync.Once{}
+	t.Cleanup(func() {
+		activeReporter = TextReporter{}
+		reporterSetByUser = false
+		autoReporterOnce = sync.Once{}
+	})
+}
+
+func TestGetActiveReporterSelectsFromReportPathFlag(t *testing.T) {
+	resetActiveReporter(t)
+
+	origArgs := args
+	defer func() { args = origArgs }()
+
+	args = &arguments{reportPath: filepath.Join(t.TempDir(), "out.ndjson")}
+	if _, ok := getActiveReporter().(*NDJSONReporter); !ok {
+		t.Errorf("getActiveReporter() = %T, want *NDJSONReporter", getActiveReporter())
+	}
+}
+
+func TestGetActiveReporterSelectsJUnitForXML(t *testing.T) {
+	resetActiveReporter(t)
+
+	origArgs := args
+	defer func() { args = origArgs }()
+
+	args = &arguments{reportPath: filepath.Join(t.TempDir(), "out.xml")}
+	if _, ok := getActiveReporter().(*JUnitReporter); !ok {
+		t.Errorf("getActiveReporter() = %T, want *JUnitReporter", getActiveReporter())
+	}
+}
+
+func TestSetReporterOverridesAutoSelection(t *testing.T) {
+	resetActiveReporter(t)
+
+	origArgs := args
+	defer func() { args = origArgs }()
+
+	args = &arguments{reportPath: filepath.Join(t.TempDir(), "out.xml")}
+	custom := &NDJSONReporter{Path: "custom.ndjson"}
+	SetReporter(custom)
+
+	if got := getActiveReporter(); got != Reporter(custom) {
+		t.Errorf("getActiveReporter() = %v, want the reporter set via SetReporter", got)
+	}
+}
@@ -0,0 +1,74 @@
+package abide
+
+import (
+	"context"
+	"time"
+)
+
+// SnapshotFile describes one persisted snapshot bundle within a
+// SnapshotStore, without its contents.
+type SnapshotFile struct {
+	// Path identifies the bundle within the store. For FSStore this is a
+	// filesystem path; for S3Store it's an object key.
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// SnapshotStore abstracts where snapshot bundles are read from and
+// written to, e.g. the local filesystem or an object store.
+type SnapshotStore interface {
+	// List returns every snapshot bundle currently in the store.
+	List(ctx context.Context) ([]SnapshotFile, error)
+	// Load returns the contents of the bundle at path.
+	Load(ctx context.Context, path string) ([]byte, error)
+	// Save writes data as the bundle at path, creating or overwriting it.
+	Save(ctx context.Context, path string, data []byte) error
+	// Delete removes the bundle at path.
+	Delete(ctx context.Context, path string) error
+}
+
+// RetentionPolicy bounds how many historical snapshot revisions a
+// RetainingStore keeps when Cleanup runs. A zero value disables that
+// dimension of pruning.
+type RetentionPolicy struct {
+	// RetentionCount keeps at most this many revisions of a given bundle.
+	RetentionCount int
+	// RetentionAge discards revisions older than this.
+	RetentionAge time.Duration
+}
+
+// RetainingStore is implemented by stores that can enumerate and prune
+// historical revisions of a bundle, rather than just its current contents.
+// CompressedStore implements it; plain FSStore and S3Store do not, since
+// neither keeps more than one revision on disk.
+type RetainingStore interface {
+	SnapshotStore
+	// Prune removes revisions of path that fall outside policy, returning
+	// the number removed.
+	Prune(ctx context.Context, path string, policy RetentionPolicy) (int, error)
+}
+
+// activeRetentionPolicy is evaluated against activeStore during Cleanup,
+// when activeStore is a RetainingStore. The zero value disables pruning.
+var activeRetentionPolicy RetentionPolicy
+
+// SetRetentionPolicy configures how many historical snapshot revisions are
+// kept when Cleanup runs. It only has an effect when the active store (see
+// SetStore) is a RetainingStore, such as CompressedStore.
+func SetRetentionPolicy(p RetentionPolicy) {
+	activeRetentionPolicy = p
+}
+
+// activeStore is the SnapshotStore all snapshot persistence goes through.
+// It defaults to an FSStore rooted at findOrCreateSnapshotDirectory, i.e.
+// today's on-disk behavior.
+var activeStore SnapshotStore = &FSStore{}
+
+// SetStore overrides the SnapshotStore abide reads snapshots from and
+// writes them to. Call it once, before any assertions run (e.g. from
+// TestMain), to redirect persistence to S3, wrap it in compression, or
+// supply a RetainingStore with a RetentionPolicy.
+func SetStore(s SnapshotStore) {
+	activeStore = s
+}
@@ -0,0 +1,225 @@
+package abide
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	compressedExt = ".zip"
+	metadataExt   = ".metadata"
+
+	// revisionMarker introduces the timestamp suffix Save gives a bundle's
+	// previous revision before overwriting it, e.g.
+	// "pkg.snapshot.rev-20060102150405.000000000.zip". List filters these
+	// out so only the current revision is ever visible as a snapshot.
+	revisionMarker  = ".rev-"
+	revisionTimeFmt = "20060102150405.000000000"
+)
+
+// CompressedStore wraps another SnapshotStore, transparently zipping each
+// bundle before it reaches the wrapped store and unzipping it on the way
+// back out. A "<pkg>.snapshot.zip" bundle is accompanied by a
+// "<pkg>.snapshot.metadata" sidecar recording each record's id, size and
+// content hash.
+type CompressedStore struct {
+	Inner SnapshotStore
+}
+
+// NewCompressedStore wraps inner in a CompressedStore.
+func NewCompressedStore(inner SnapshotStore) *CompressedStore {
+	return &CompressedStore{Inner: inner}
+}
+
+// snapshotMetadata is one record in a bundle's .metadata sidecar.
+type snapshotMetadata struct {
+	ID     string `json:"id"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+func (c *CompressedStore) List(ctx context.Context) ([]SnapshotFile, error) {
+	files, err := c.Inner.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []SnapshotFile
+	for _, f := range files {
+		if !strings.HasSuffix(f.Path, compressedExt) || strings.Contains(f.Path, revisionMarker) {
+			continue
+		}
+		out = append(out, SnapshotFile{
+			Path:    strings.TrimSuffix(f.Path, compressedExt),
+			Size:    f.Size,
+			ModTime: f.ModTime,
+		})
+	}
+
+	return out, nil
+}
+
+func (c *CompressedStore) Load(ctx context.Context, path string) ([]byte, error) {
+	zipped, err := c.Inner.Load(ctx, path+compressedExt)
+	if err != nil {
+		return nil, err
+	}
+	return unzipBundle(path, zipped)
+}
+
+// Save writes the new revision of path's bundle, first preserving whatever
+// revision is already in Inner (if any) under a timestamped name so
+// Prune has a history to enforce RetentionPolicy against.
+func (c *CompressedStore) Save(ctx context.Context, path string, data []byte) error {
+	if err := c.preserveRevision(ctx, path); err != nil {
+		return err
+	}
+
+	zipped, err := zipBundle(path, data)
+	if err != nil {
+		return err
+	}
+	if err := c.Inner.Save(ctx, path+compressedExt, zipped); err != nil {
+		return err
+	}
+
+	meta, err := buildMetadata(data)
+	if err != nil {
+		return err
+	}
+	return c.Inner.Save(ctx, path+metadataExt, meta)
+}
+
+// preserveRevision copies path's current bundle and metadata, if present,
+// to a revision-marked name before Save overwrites them in place.
+func (c *CompressedStore) preserveRevision(ctx context.Context, path string) error {
+	zipped, err := c.Inner.Load(ctx, path+compressedExt)
+	if err != nil {
+		return nil // no existing revision to preserve
+	}
+
+	rev := path + revisionMarker + time.Now().UTC().Format(revisionTimeFmt)
+	if err := c.Inner.Save(ctx, rev+compressedExt, zipped); err != nil {
+		return err
+	}
+
+	if meta, err := c.Inner.Load(ctx, path+metadataExt); err == nil {
+		if err := c.Inner.Save(ctx, rev+metadataExt, meta); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *CompressedStore) Delete(ctx context.Context, path string) error {
+	if err := c.Inner.Delete(ctx, path+compressedExt); err != nil {
+		return err
+	}
+	return c.Inner.Delete(ctx, path+metadataExt)
+}
+
+// Prune implements RetainingStore by enumerating the revisions Save has
+// preserved for path (see preserveRevision) and removing whichever fall
+// outside policy, oldest first. The current revision is never pruned.
+func (c *CompressedStore) Prune(ctx context.Context, path string, policy RetentionPolicy) (int, error) {
+	files, err := c.Inner.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	prefix := path + revisionMarker
+	var revisions []SnapshotFile
+	for _, f := range files {
+		if strings.HasPrefix(f.Path, prefix) && strings.HasSuffix(f.Path, compressedExt) {
+			revisions = append(revisions, f)
+		}
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].ModTime.After(revisions[j].ModTime) })
+
+	var removed int
+	for i, f := range revisions {
+		expired := policy.RetentionAge > 0 && time.Since(f.ModTime) > policy.RetentionAge
+		overCount := policy.RetentionCount > 0 && i >= policy.RetentionCount
+		if !expired && !overCount {
+			continue
+		}
+
+		rev := strings.TrimSuffix(f.Path, compressedExt)
+		if err := c.Inner.Delete(ctx, rev+compressedExt); err != nil {
+			return removed, err
+		}
+		_ = c.Inner.Delete(ctx, rev+metadataExt)
+		removed++
+	}
+
+	return removed, nil
+}
+
+func zipBundle(path string, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+
+	entry, err := w.Create(filepath.Base(path))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := entry.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func unzipBundle(path string, zipped []byte) ([]byte, error) {
+	r, err := zip.NewReader(bytes.NewReader(zipped), int64(len(zipped)))
+	if err != nil {
+		return nil, err
+	}
+	if len(r.File) == 0 {
+		return nil, fmt.Errorf("%s: empty snapshot bundle", path)
+	}
+
+	f, err := r.File[0].Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ioutil.ReadAll(f)
+}
+
+// buildMetadata decodes data's snapshot records to produce the .metadata
+// sidecar contents, sorted by id for a stable diff.
+func buildMetadata(data []byte) ([]byte, error) {
+	snaps, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]snapshotMetadata, 0, len(snaps))
+	for id, s := range snaps {
+		sum := sha256.Sum256([]byte(s.value))
+		records = append(records, snapshotMetadata{
+			ID:     string(id),
+			Size:   len(s.value),
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+
+	return json.MarshalIndent(records, "", "  ")
+}
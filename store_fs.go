@@ -0,0 +1,58 @@
+package abide
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// FSStore is a SnapshotStore backed by the local filesystem, rooted at
+// SnapshotsDir. It's the zero-config default and matches abide's original
+// on-disk layout.
+type FSStore struct{}
+
+// NewFSStore returns an FSStore. It exists alongside the zero-value
+// literal for symmetry with the other store constructors.
+func NewFSStore() *FSStore {
+	return &FSStore{}
+}
+
+func (s *FSStore) List(ctx context.Context) ([]SnapshotFile, error) {
+	dir, err := findOrCreateSnapshotDirectory()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []SnapshotFile
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		if !isSnapshot(path) {
+			continue
+		}
+		files = append(files, SnapshotFile{
+			Path:    path,
+			Size:    e.Size(),
+			ModTime: e.ModTime(),
+		})
+	}
+
+	return files, nil
+}
+
+func (s *FSStore) Load(ctx context.Context, path string) ([]byte, error) {
+	return ioutil.ReadFile(path)
+}
+
+func (s *FSStore) Save(ctx context.Context, path string, data []byte) error {
+	return ioutil.WriteFile(path, data, 0666)
+}
+
+func (s *FSStore) Delete(ctx context.Context, path string) error {
+	return os.Remove(path)
+}
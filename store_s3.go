@@ -0,0 +1,111 @@
+package abide
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Store is a SnapshotStore backed by an S3-compatible object store.
+type S3Store struct {
+	Bucket string
+	// Prefix is prepended to every object key, letting multiple projects
+	// or branches share a bucket without colliding.
+	Prefix string
+
+	client *minio.Client
+}
+
+// S3StoreConfig configures a new S3Store.
+type S3StoreConfig struct {
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+	Bucket          string
+	Prefix          string
+	UseTLS          bool
+}
+
+// NewS3Store connects to the S3-compatible endpoint described by cfg and
+// returns a store backed by it.
+func NewS3Store(cfg S3StoreConfig) (*S3Store, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
+		Secure: cfg.UseTLS,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &S3Store{
+		Bucket: cfg.Bucket,
+		Prefix: cfg.Prefix,
+		client: client,
+	}, nil
+}
+
+func (s *S3Store) key(path string) string {
+	if s.Prefix == "" {
+		return path
+	}
+	return s.Prefix + "/" + path
+}
+
+func (s *S3Store) List(ctx context.Context) ([]SnapshotFile, error) {
+	var files []SnapshotFile
+
+	// listPrefix is s.key("") - the same join s.key uses for Load/Save/
+	// Delete - so a bare "proj-a" Prefix queries "proj-a/" and doesn't also
+	// match an unrelated "proj-ab/..." key.
+	listPrefix := s.key("")
+
+	for obj := range s.client.ListObjects(ctx, s.Bucket, minio.ListObjectsOptions{
+		Prefix:    listPrefix,
+		Recursive: true,
+	}) {
+		if obj.Err != nil {
+			return nil, obj.Err
+		}
+		if !isSnapshot(obj.Key) {
+			continue
+		}
+		// obj.Key is prefixed (ListObjectsOptions.Prefix above), but
+		// Load/Save/Delete prefix path themselves via s.key, so strip it
+		// here to keep Path round-trippable through List -> Load.
+		path := strings.TrimPrefix(obj.Key, listPrefix)
+		files = append(files, SnapshotFile{
+			Path:    path,
+			Size:    obj.Size,
+			ModTime: obj.LastModified,
+		})
+	}
+
+	return files, nil
+}
+
+func (s *S3Store) Load(ctx context.Context, path string) ([]byte, error) {
+	obj, err := s.client.GetObject(ctx, s.Bucket, s.key(path), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+
+	return ioutil.ReadAll(obj)
+}
+
+func (s *S3Store) Save(ctx context.Context, path string, data []byte) error {
+	_, err := s.client.PutObject(
+		ctx, s.Bucket, s.key(path),
+		bytes.NewReader(data), int64(len(data)),
+		minio.PutObjectOptions{},
+	)
+	return err
+}
+
+func (s *S3Store) Delete(ctx context.Context, path string) error {
+	return s.client.RemoveObject(ctx, s.Bucket, s.key(path), minio.RemoveObjectOptions{})
+}
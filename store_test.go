@@ -0,0 +1,123 @@
+package abide
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errMemStoreMissing = errors.New("memStore: no such path")
+
+// memStore is an in-memory SnapshotStore used to exercise CompressedStore
+// and S3Store logic without touching the filesystem or a real bucket.
+type memStore struct {
+	data map[string][]byte
+}
+
+func newMemStore() *memStore {
+	return &memStore{data: map[string][]byte{}}
+}
+
+func (m *memStore) List(ctx context.Context) ([]SnapshotFile, error) {
+	var files []SnapshotFile
+	for path, data := range m.data {
+		if !isSnapshot(path) {
+			continue
+		}
+		files = append(files, SnapshotFile{Path: path, Size: int64(len(data)), ModTime: time.Now()})
+	}
+	return files, nil
+}
+
+func (m *memStore) Load(ctx context.Context, path string) ([]byte, error) {
+	data, ok := m.data[path]
+	if !ok {
+		return nil, errMemStoreMissing
+	}
+	return data, nil
+}
+
+func (m *memStore) Save(ctx context.Context, path string, data []byte) error {
+	m.data[path] = data
+	return nil
+}
+
+func (m *memStore) Delete(ctx context.Context, path string) error {
+	delete(m.data, path)
+	return nil
+}
+
+func TestIsSnapshot(t *testing.T) {
+	cases := map[string]bool{
+		"pkg.snapshot":          true,
+		"pkg.snapshot.zip":      true,
+		"pkg.snapshot.metadata": true,
+		"pkg.txt":               false,
+	}
+
+	for path, want := range cases {
+		if got := isSnapshot(path); got != want {
+			t.Errorf("isSnapshot(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestCompressedStoreRoundTrip(t *testing.T) {
+	inner := newMemStore()
+	store := NewCompressedStore(inner)
+	ctx := context.Background()
+
+	path := "dir/pkg.snapshot"
+	data := []byte(snapshotSeparator + "id1 */\nvalue1\n")
+
+	if err := store.Save(ctx, path, data); err != nil {
+		t.Fatalf("Save: %s", err)
+	}
+
+	files, err := store.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %s", err)
+	}
+	if len(files) != 1 || files[0].Path != path {
+		t.Fatalf("List = %+v, want a single entry for %q", files, path)
+	}
+
+	got, err := store.Load(ctx, path)
+	if err != nil {
+		t.Fatalf("Load: %s", err)
+	}
+	if string(got) != string(data) {
+		t.Errorf("Load = %q, want %q", got, data)
+	}
+}
+
+func TestCompressedStoreRetentionCount(t *testing.T) {
+	inner := newMemStore()
+	store := NewCompressedStore(inner)
+	ctx := context.Background()
+
+	path := "dir/pkg.snapshot"
+	for i := 0; i < 3; i++ {
+		data := []byte(snapshotSeparator + "id1 */\nvalue\n")
+		if err := store.Save(ctx, path, data); err != nil {
+			t.Fatalf("Save %d: %s", i, err)
+		}
+	}
+
+	removed, err := store.Prune(ctx, path, RetentionPolicy{RetentionCount: 1})
+	if err != nil {
+		t.Fatalf("Prune: %s", err)
+	}
+	if removed != 1 {
+		t.Errorf("Prune removed %d revisions, want 1 (3 saves keep 2 revisions, retain 1)", removed)
+	}
+
+	files, err := store.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %s", err)
+	}
+	if len(files) != 1 {
+		t.Errorf("List after Prune = %+v, want the single current revision", files)
+	}
+}